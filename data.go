@@ -0,0 +1,164 @@
+package retries
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// DataFunc is a function that can be retried and that returns a
+	// value on success.
+	DataFunc[T any] func() (T, error)
+
+	// FullDataFunc behaves like DataFunc, but with the extended
+	// interface offered by FullFunc.
+	FullDataFunc[T any] func(retryNum int, lastRetry time.Time) (T, error)
+
+	// DataFuncCtx behaves like DataFunc, but is context.Context-aware,
+	// following the same semantics as FuncCtx.
+	DataFuncCtx[T any] func(ctx context.Context) (T, error)
+
+	// FullDataFuncCtx behaves like DataFuncCtx, but with the extended
+	// interface offered by FullFuncCtx.
+	FullDataFuncCtx[T any] func(ctx context.Context, retryNum int, lastRetry time.Time) (T, error)
+
+	// DataRetrier is a type that manages retries of functions that
+	// return a value of type T on success.
+	DataRetrier[T any] struct {
+		f   interface{}
+		cfg retrierConfig
+	}
+)
+
+// NewWithData initializes a new DataRetrier[T]. This behaves similar to
+// New, but accepting a DataFunc[T], which returns a value on success.
+func NewWithData[T any](f DataFunc[T], args ...Arg) *DataRetrier[T] {
+	r := &DataRetrier[T]{
+		f: f,
+	}
+	for _, a := range args {
+		a(&r.cfg)
+	}
+
+	r.cfg.setDefaults()
+
+	return r
+}
+
+// NewFullWithData initializes a new DataRetrier[T]. This behaves similar
+// to NewFull, but accepting a FullDataFunc[T], which returns a value
+// on success.
+func NewFullWithData[T any](f FullDataFunc[T], args ...Arg) *DataRetrier[T] {
+	r := &DataRetrier[T]{
+		f: f,
+	}
+	for _, a := range args {
+		a(&r.cfg)
+	}
+
+	r.cfg.setDefaults()
+
+	return r
+}
+
+// NewCtxWithData initializes a new DataRetrier[T]. This behaves similar
+// to NewCtx, but accepting a DataFuncCtx[T], which returns a value on
+// success.
+func NewCtxWithData[T any](f DataFuncCtx[T], args ...Arg) *DataRetrier[T] {
+	r := &DataRetrier[T]{
+		f: f,
+	}
+	for _, a := range args {
+		a(&r.cfg)
+	}
+
+	r.cfg.setDefaults()
+
+	return r
+}
+
+// NewFullCtxWithData initializes a new DataRetrier[T]. This behaves
+// similar to NewFullCtx, but accepting a FullDataFuncCtx[T], which
+// returns a value on success.
+func NewFullCtxWithData[T any](f FullDataFuncCtx[T], args ...Arg) *DataRetrier[T] {
+	r := &DataRetrier[T]{
+		f: f,
+	}
+	for _, a := range args {
+		a(&r.cfg)
+	}
+
+	r.cfg.setDefaults()
+
+	return r
+}
+
+// Try runs the retry process until the number of retries is
+// exhausted, returning the value produced by the final successful
+// attempt.
+func (r *DataRetrier[T]) Try() (T, error) {
+	return r.TryContext(context.Background())
+}
+
+// TryContext behaves like Try, but aborts early if ctx is cancelled
+// or its deadline is exceeded.
+func (r *DataRetrier[T]) TryContext(ctx context.Context) (T, error) {
+	var result T
+	var err error
+	var lastRetryTime time.Time
+
+	for i := 0; i < r.cfg.retries; i++ {
+		startTime := r.cfg.clock.Now()
+
+		attemptCtx := ctx
+		cancel := func() {}
+		if r.cfg.attemptTimeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, r.cfg.attemptTimeout)
+		}
+
+		switch f := r.f.(type) {
+		case DataFunc[T]:
+			result, err = f()
+		case FullDataFunc[T]:
+			result, err = f(i, lastRetryTime)
+		case DataFuncCtx[T]:
+			result, err = f(attemptCtx)
+		case FullDataFuncCtx[T]:
+			result, err = f(attemptCtx, i, lastRetryTime)
+		default:
+			panic("invalid function interface")
+		}
+		cancel()
+
+		lastRetryTime = startTime
+
+		if err == nil {
+			return result, nil
+		}
+
+		if (i != r.cfg.retries-1) && r.cfg.retryCheck(err) {
+			nextSleep := r.cfg.backoff(i)
+			if r.cfg.onRetry != nil {
+				r.cfg.onRetry(i, err, nextSleep)
+			}
+			if sleepErr := r.cfg.clock.SleepCtx(ctx, nextSleep); sleepErr != nil {
+				var zero T
+				return zero, sleepErr
+			}
+			continue
+		}
+
+		break
+	}
+
+	var zero T
+	return zero, err
+}
+
+// DoWithData runs fn until it succeeds or the number of retries is
+// exhausted, returning the value produced by the final successful
+// attempt. This spares callers from having to smuggle a result out of
+// fn through a closure variable.
+func DoWithData[T any](fn func() (T, error), args ...Arg) (T, error) {
+	return NewWithData(DataFunc[T](fn), args...).Try()
+}
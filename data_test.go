@@ -0,0 +1,99 @@
+package retries
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDataRetrier(t *testing.T) {
+	t.Run("do-with-data-success", func(t *testing.T) {
+		calls := 0
+		result, err := DoWithData(func() (int, error) {
+			calls++
+			return 42, nil
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != 42 {
+			t.Fatalf("invalid result: %d", result)
+		}
+		if calls != 1 {
+			t.Fatal("invalid number of calls")
+		}
+	})
+
+	t.Run("do-with-data-eventual-success", func(t *testing.T) {
+		c := &clockMock{}
+
+		e := errors.New("my error")
+
+		calls := 0
+		result, err := DoWithData(func() (string, error) {
+			calls++
+			if calls < 3 {
+				return "", e
+			}
+			return "done", nil
+		}, WithClock(c), WithRetries(5))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != "done" {
+			t.Fatalf("invalid result: %q", result)
+		}
+		if calls != 3 {
+			t.Fatal("invalid number of calls")
+		}
+	})
+
+	t.Run("do-with-data-exhausted", func(t *testing.T) {
+		c := &clockMock{}
+
+		e := errors.New("my error")
+
+		calls := 0
+		result, err := DoWithData(func() (int, error) {
+			calls++
+			return 0, e
+		}, WithClock(c), WithRetries(3))
+
+		if !errors.Is(err, e) {
+			t.Fatalf("invalid error returned: %s", err)
+		}
+		if result != 0 {
+			t.Fatalf("invalid result: %d", result)
+		}
+		if calls != 3 {
+			t.Fatal("invalid number of calls")
+		}
+	})
+
+	t.Run("full-data-func", func(t *testing.T) {
+		c := &clockMock{}
+
+		calls := 0
+		r := NewFullWithData(func(i int, _ time.Time) (int, error) {
+			calls++
+			if i < 2 {
+				return 0, errors.New("my error")
+			}
+			return i, nil
+		}, WithClock(c), WithRetries(5))
+
+		result, err := r.Try()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != 2 {
+			t.Fatalf("invalid result: %d", result)
+		}
+		if calls != 3 {
+			t.Fatal("invalid number of calls")
+		}
+	})
+}
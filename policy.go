@@ -0,0 +1,100 @@
+package retries
+
+import "errors"
+
+// RetryPolicy decides whether an error returned by a retried function
+// should trigger a retry. Policies are composable: build one up from
+// RetryIfIs, RetryIfAs, RetryIfTemporary, RetryIfStatus, and the Any/
+// All combinators, then install it with WithPolicy.
+type RetryPolicy func(error) bool
+
+// RetryIfIs returns a RetryPolicy that retries when the error matches
+// any of targets, as determined by errors.Is.
+func RetryIfIs(targets ...error) RetryPolicy {
+	return func(err error) bool {
+		for _, target := range targets {
+			if errors.Is(err, target) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// RetryIfAs returns a RetryPolicy that retries when the error chain
+// contains an error assignable to T, as determined by errors.As.
+func RetryIfAs[T error]() RetryPolicy {
+	return func(err error) bool {
+		var target T
+		return errors.As(err, &target)
+	}
+}
+
+// RetryIfTemporary returns a RetryPolicy that retries when the error
+// implements `interface{ Temporary() bool }` and reports itself as
+// temporary. This is useful for net.Error and similar errors.
+func RetryIfTemporary() RetryPolicy {
+	return func(err error) bool {
+		temp, ok := err.(interface{ Temporary() bool })
+		return ok && temp.Temporary()
+	}
+}
+
+// RetryIfStatus returns a RetryPolicy that retries when the error
+// implements `interface{ StatusCode() int }` and its status code is
+// one of codes. This is meant for errors wrapping HTTP responses.
+func RetryIfStatus(codes ...int) RetryPolicy {
+	return func(err error) bool {
+		sc, ok := err.(interface{ StatusCode() int })
+		if !ok {
+			return false
+		}
+
+		for _, code := range codes {
+			if sc.StatusCode() == code {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// Any returns a RetryPolicy that retries when any of policies would
+// retry.
+func Any(policies ...RetryPolicy) RetryPolicy {
+	return func(err error) bool {
+		for _, p := range policies {
+			if p(err) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// All returns a RetryPolicy that retries only when every one of
+// policies would retry.
+func All(policies ...RetryPolicy) RetryPolicy {
+	return func(err error) bool {
+		for _, p := range policies {
+			if !p(err) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// WithPolicy sets the RetryPolicy that determines whether an error
+// should be retried. This is the typed replacement for WithWhitelist.
+func WithPolicy(policy RetryPolicy) Arg {
+	return func(cfg *retrierConfig) *retrierConfig {
+		cfg.retryCheck = policy
+
+		return cfg
+	}
+}
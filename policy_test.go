@@ -0,0 +1,116 @@
+package retries
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type tempError struct{ temporary bool }
+
+func (e *tempError) Error() string   { return "temp error" }
+func (e *tempError) Temporary() bool { return e.temporary }
+
+type statusError struct{ code int }
+
+func (e *statusError) Error() string   { return fmt.Sprintf("status %d", e.code) }
+func (e *statusError) StatusCode() int { return e.code }
+
+type customError struct{ msg string }
+
+func (e *customError) Error() string { return e.msg }
+
+func TestRetryPolicies(t *testing.T) {
+	t.Run("retry-if-is", func(t *testing.T) {
+		e := errors.New("boom")
+		policy := RetryIfIs(e)
+
+		if !policy(fmt.Errorf("wrapped: %w", e)) {
+			t.Fatal("expected policy to match wrapped target")
+		}
+		if policy(errors.New("boom")) {
+			t.Fatal("expected policy not to match a distinct error with the same message")
+		}
+	})
+
+	t.Run("retry-if-as", func(t *testing.T) {
+		policy := RetryIfAs[*customError]()
+
+		if !policy(&customError{msg: "bad"}) {
+			t.Fatal("expected policy to match *customError")
+		}
+		if policy(errors.New("bad")) {
+			t.Fatal("expected policy not to match a non-customError")
+		}
+	})
+
+	t.Run("retry-if-temporary", func(t *testing.T) {
+		policy := RetryIfTemporary()
+
+		if !policy(&tempError{temporary: true}) {
+			t.Fatal("expected policy to match a temporary error")
+		}
+		if policy(&tempError{temporary: false}) {
+			t.Fatal("expected policy not to match a non-temporary error")
+		}
+		if policy(errors.New("boom")) {
+			t.Fatal("expected policy not to match a non-Temporary error")
+		}
+	})
+
+	t.Run("retry-if-status", func(t *testing.T) {
+		policy := RetryIfStatus(429, 503)
+
+		if !policy(&statusError{code: 503}) {
+			t.Fatal("expected policy to match status 503")
+		}
+		if policy(&statusError{code: 404}) {
+			t.Fatal("expected policy not to match status 404")
+		}
+		if policy(errors.New("boom")) {
+			t.Fatal("expected policy not to match a non-status error")
+		}
+	})
+
+	t.Run("any", func(t *testing.T) {
+		policy := Any(RetryIfStatus(429), RetryIfTemporary())
+
+		if !policy(&statusError{code: 429}) {
+			t.Fatal("expected Any to match on the first policy")
+		}
+		if !policy(&tempError{temporary: true}) {
+			t.Fatal("expected Any to match on the second policy")
+		}
+		if policy(errors.New("boom")) {
+			t.Fatal("expected Any not to match when no policy matches")
+		}
+	})
+
+	t.Run("all", func(t *testing.T) {
+		policy := All(RetryIfStatus(503), RetryIfTemporary())
+
+		if policy(&statusError{code: 503}) {
+			t.Fatal("expected All not to match when only one policy matches")
+		}
+	})
+
+	t.Run("with-policy", func(t *testing.T) {
+		c := &clockMock{}
+
+		calls := 0
+		r := New(func() error {
+			calls++
+			return &statusError{code: 503}
+		}, WithClock(c), WithRetries(3), WithPolicy(RetryIfStatus(503)))
+
+		err := r.Try()
+
+		var se *statusError
+		if !errors.As(err, &se) || se.code != 503 {
+			t.Fatalf("invalid error returned: %s", err)
+		}
+		if calls != 3 {
+			t.Fatalf("invalid number of calls: %d", calls)
+		}
+	})
+}
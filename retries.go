@@ -1,9 +1,9 @@
 package retries
 
 import (
-	"errors"
+	"context"
 	"math"
-	"strings"
+	"math/rand"
 	"time"
 )
 
@@ -19,10 +19,14 @@ type (
 	Clock interface {
 		Now() time.Time
 		Sleep(time.Duration)
+
+		// SleepCtx behaves like Sleep, but returns early with
+		// ctx.Err() if ctx is done before the sleep completes.
+		SleepCtx(ctx context.Context, d time.Duration) error
 	}
 
 	// Arg is a parameter to New.
-	Arg func(*Retrier) *Retrier
+	Arg func(*retrierConfig) *retrierConfig
 
 	// Func is a function that can be retried.
 	Func func() error
@@ -31,13 +35,34 @@ type (
 	// interface with access to retry metadata.
 	FullFunc func(retryNum int, lastRetry time.Time) error
 
+	// FuncCtx is a context.Context-aware function that can be
+	// retried. The context passed to it is the one passed to
+	// TryContext (or, with WithAttemptTimeout, a context derived
+	// from it that expires after the attempt timeout), and should be
+	// respected by the function so that the retry loop can be
+	// cancelled while an attempt is in flight.
+	FuncCtx func(ctx context.Context) error
+
+	// FullFuncCtx behaves like FuncCtx, but with the extended
+	// interface offered by FullFunc.
+	FullFuncCtx func(ctx context.Context, retryNum int, lastRetry time.Time) error
+
+	// retrierConfig holds the configuration shared by Retrier and
+	// its generic counterpart, DataRetrier[T].
+	retrierConfig struct {
+		clock          Clock
+		retries        int
+		retryCheck     func(error) bool
+		backoff        func(retryNum int) time.Duration
+		rand           *rand.Rand
+		watches        []watch
+		attemptTimeout time.Duration
+		onRetry        func(retryNum int, err error, nextSleep time.Duration)
+	}
+
 	// Retrier is a type that manages retries.
 	Retrier struct {
-		f             interface{}
-		clock         Clock
-		retries       int
-		retryCheck    func(error) bool
-		sleepStrategy func(int, Clock)
+		inner *DataRetrier[struct{}]
 	}
 )
 
@@ -46,79 +71,74 @@ type (
 // three times. This behavior can be customized with functional
 // arguments using the functions in this package.
 func New(f Func, args ...Arg) *Retrier {
-	r := &Retrier{
-		f: f,
-	}
-	for _, a := range args {
-		a(r)
-	}
-
-	r.setDefaults()
+	wrapped := DataFunc[struct{}](func() (struct{}, error) {
+		return struct{}{}, f()
+	})
 
-	return r
+	return &Retrier{inner: NewWithData(wrapped, args...)}
 }
 
 // NewFull initializes a new Retrier. This behaves similar to New, but
 // accepting FullFunc functions, which offer an extended interface.
 func NewFull(f FullFunc, args ...Arg) *Retrier {
-	r := &Retrier{
-		f: f,
-	}
-	for _, a := range args {
-		a(r)
-	}
-
-	r.setDefaults()
+	wrapped := FullDataFunc[struct{}](func(retryNum int, lastRetry time.Time) (struct{}, error) {
+		return struct{}{}, f(retryNum, lastRetry)
+	})
 
-	return r
+	return &Retrier{inner: NewFullWithData(wrapped, args...)}
 }
 
-// Try runs the retry process until the number of retries is
-// exhausted.
-func (r *Retrier) Try() error {
-	var err error
-	var lastRetryTime time.Time
-
-	for i := 0; i < r.retries; i++ {
-		startTime := r.clock.Now()
-
-		if f, ok := r.f.(Func); ok {
-			err = f()
-		} else if f, ok := r.f.(FullFunc); ok {
-			err = f(i, lastRetryTime)
-		} else {
-			panic("invalid function interface")
-		}
+// NewCtx initializes a new Retrier whose function accepts a
+// context.Context. This behaves similar to New, but the retried
+// function is given the context passed to TryContext so that it can
+// observe cancellation while an attempt is in flight.
+func NewCtx(f FuncCtx, args ...Arg) *Retrier {
+	wrapped := DataFuncCtx[struct{}](func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, f(ctx)
+	})
 
-		lastRetryTime = startTime
+	return &Retrier{inner: NewCtxWithData(wrapped, args...)}
+}
 
-		if err == nil {
-			return nil
-		}
+// NewFullCtx initializes a new Retrier whose function accepts a
+// context.Context. This behaves similar to NewFull, but the retried
+// function is given the context passed to TryContext.
+func NewFullCtx(f FullFuncCtx, args ...Arg) *Retrier {
+	wrapped := FullDataFuncCtx[struct{}](func(ctx context.Context, retryNum int, lastRetry time.Time) (struct{}, error) {
+		return struct{}{}, f(ctx, retryNum, lastRetry)
+	})
 
-		if (i != r.retries-1) && r.retryCheck(err) {
-			r.sleepStrategy(i, r.clock)
-			continue
-		}
+	return &Retrier{inner: NewFullCtxWithData(wrapped, args...)}
+}
 
-		break
-	}
+// Try runs the retry process until the number of retries is
+// exhausted.
+func (r *Retrier) Try() error {
+	_, err := r.inner.Try()
+	return err
+}
 
+// TryContext behaves like Try, but aborts early if ctx is cancelled
+// or its deadline is exceeded, either while an attempt is in flight
+// (for retriers built with NewCtx/NewFullCtx) or while sleeping
+// between attempts.
+func (r *Retrier) TryContext(ctx context.Context) error {
+	_, err := r.inner.TryContext(ctx)
 	return err
 }
 
-func (r *Retrier) setDefaults() {
-	if r.clock == nil {
-		r.clock = &clock{}
+func (cfg *retrierConfig) setDefaults() {
+	if cfg.clock == nil {
+		cfg.clock = &clock{}
 	}
-	if r.retries == 0 {
-		r.retries = defaultRetries
+	if cfg.retries == 0 {
+		cfg.retries = defaultRetries
 	}
-	if r.retryCheck == nil {
-		r.retryCheck = RetryOnAllErrors
+	if cfg.retryCheck == nil {
+		cfg.retryCheck = RetryOnAllErrors
 	}
-	if r.sleepStrategy == nil {
-		WithExpBackoff(defaultBackoffFactor)(r)
+	if cfg.backoff == nil {
+		WithExpBackoff(defaultBackoffFactor)(cfg)
 	}
 }
 
@@ -130,6 +150,18 @@ func (c *clock) Now() time.Time {
 	return time.Now()
 }
 
+func (c *clock) SleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
 // RetryOnAllErrors is a retry check that retries on all errors.
 func RetryOnAllErrors(err error) bool {
 	return err != nil
@@ -137,10 +169,10 @@ func RetryOnAllErrors(err error) bool {
 
 // WithRetries sets the number of retries for a Retrier.
 func WithRetries(retries int) Arg {
-	return func(r *Retrier) *Retrier {
-		r.retries = retries
+	return func(cfg *retrierConfig) *retrierConfig {
+		cfg.retries = retries
 
-		return r
+		return cfg
 	}
 }
 
@@ -148,105 +180,167 @@ func WithRetries(retries int) Arg {
 // base. For retry number `i`, this strategy sleeps for `factor ** i`
 // seconds.
 func WithExpBackoff(factor int) Arg {
-	return func(r *Retrier) *Retrier {
-		r.sleepStrategy = func(retryNum int, clock Clock) {
+	return func(cfg *retrierConfig) *retrierConfig {
+		cfg.backoff = func(retryNum int) time.Duration {
 			s := math.Pow(float64(factor), float64(retryNum))
-			clock.Sleep(time.Second * time.Duration(s))
+			return time.Second * time.Duration(s)
 		}
 
-		return r
+		return cfg
 	}
 }
 
 // WithConstantBackoff defines a back-off strategy with where the
 // sleep time is constant between retries.
 func WithConstantBackoff(backoff time.Duration) Arg {
-	return func(r *Retrier) *Retrier {
-		r.sleepStrategy = func(retryNum int, clock Clock) {
-			clock.Sleep(backoff)
+	return func(cfg *retrierConfig) *retrierConfig {
+		cfg.backoff = func(retryNum int) time.Duration {
+			return backoff
 		}
 
-		return r
+		return cfg
 	}
 }
 
-// WithWhitelist defines a retry condition where the error has to be
-// contained within a whitelist of errors. Errors are compared using
-// `errors.Is` from the standard library, by comparing error strings
-// after unwrapping errors (using stdlib error wrapping and pkg/errors
-// error wrapping), and by checking if the whitelisted error is a
-// substring of the returned error.
-func WithWhitelist(whitelist ...error) Arg {
-	return func(r *Retrier) *Retrier {
-		r.retryCheck = func(err error) bool {
-			for _, e := range whitelist {
-				if errors.Is(err, e) {
-					return true
-				}
-
-				// Stdlib error wrapping
-				if err, ok := err.(interface {
-					Unwrap() error
-				}); ok {
-					if err.Unwrap().Error() == e.Error() ||
-						strings.Contains(err.Unwrap().Error(), e.Error()) {
-
-						return true
-					}
-				}
-
-				// pkg/errors error wrapping
-				if err, ok := err.(interface {
-					Cause() error
-				}); ok {
-					if err.Cause().Error() == e.Error() ||
-						strings.Contains(err.Cause().Error(), e.Error()) {
-
-						return true
-					}
-				}
-
-				if err.Error() == e.Error() ||
-					strings.Contains(err.Error(), e.Error()) {
-
-					return true
-				}
-			}
-
-			return false
+// WithExpBackoffJitter behaves like WithExpBackoff, but caps the
+// exponential delay at maxInterval and then applies full jitter
+// (a random duration in [0, d)) to the capped delay. This avoids the
+// thundering-herd problem where many callers retrying the same
+// operation end up sleeping for the same duration in lockstep.
+func WithExpBackoffJitter(factor int, maxInterval time.Duration) Arg {
+	return func(cfg *retrierConfig) *retrierConfig {
+		cfg.backoff = func(retryNum int) time.Duration {
+			d := cappedExpBackoff(factor, retryNum, maxInterval)
+			return time.Duration(cfg.int63n(int64(d)))
 		}
 
-		return r
+		return cfg
 	}
 }
 
+// WithExpBackoffEqualJitter behaves like WithExpBackoffJitter, but
+// applies equal jitter instead of full jitter: half of the capped
+// delay is always slept, and a random duration in [0, d/2) is added
+// on top. This keeps a higher sleep floor than full jitter while
+// still spreading retries out over time.
+func WithExpBackoffEqualJitter(factor int, maxInterval time.Duration) Arg {
+	return func(cfg *retrierConfig) *retrierConfig {
+		cfg.backoff = func(retryNum int) time.Duration {
+			d := cappedExpBackoff(factor, retryNum, maxInterval)
+			half := d / 2
+			return half + time.Duration(cfg.int63n(int64(half)))
+		}
+
+		return cfg
+	}
+}
+
+// cappedExpBackoff computes the same exponential delay as
+// WithExpBackoff, capped at maxInterval.
+func cappedExpBackoff(factor, retryNum int, maxInterval time.Duration) time.Duration {
+	s := math.Pow(float64(factor), float64(retryNum))
+	d := time.Second * time.Duration(s)
+	if d > maxInterval {
+		d = maxInterval
+	}
+
+	return d
+}
+
+// int63n returns a random number in [0, n) using the retrier's
+// injected rand.Rand, if any, or the math/rand package-level source
+// otherwise. It returns 0 for n <= 0, since rand.Int63n panics in
+// that case.
+func (cfg *retrierConfig) int63n(n int64) int64 {
+	if n <= 0 {
+		return 0
+	}
+	if cfg.rand != nil {
+		return cfg.rand.Int63n(n)
+	}
+
+	return rand.Int63n(n)
+}
+
+// WithRand sets the source of randomness used by jittered backoff
+// strategies such as WithExpBackoffJitter. Use this to make jitter
+// deterministic in tests.
+func WithRand(r *rand.Rand) Arg {
+	return func(cfg *retrierConfig) *retrierConfig {
+		cfg.rand = r
+
+		return cfg
+	}
+}
+
+// WithWhitelist defines a retry condition where the error has to
+// match one of whitelist, as determined by errors.Is.
+//
+// Deprecated: use WithPolicy(RetryIfIs(whitelist...)) instead.
+// WithWhitelist used to also fall back to comparing error strings,
+// including substring matches, which was a foot-gun (e.g. a
+// whitelisted "long error" would match a returned "my long error");
+// RetryPolicy-based matching doesn't do that.
+func WithWhitelist(whitelist ...error) Arg {
+	return WithPolicy(RetryIfIs(whitelist...))
+}
+
 // WithClock sets a custom clock type for the Retrier. Use this to
 // mock out the time calls a Retrier makes.
 func WithClock(c Clock) Arg {
-	return func(r *Retrier) *Retrier {
-		r.clock = c
+	return func(cfg *retrierConfig) *retrierConfig {
+		cfg.clock = c
 
-		return r
+		return cfg
 	}
 }
 
 // WithRetryCheck allows the caller to customize the function that
 // determines whether an error should be retried.
 func WithRetryCheck(chk func(error) bool) Arg {
-	return func(r *Retrier) *Retrier {
-		r.retryCheck = chk
+	return func(cfg *retrierConfig) *retrierConfig {
+		cfg.retryCheck = chk
 
-		return r
+		return cfg
 	}
 }
 
-// WithSleepStrategy sets a custom sleeping strategy. This function
-// runs after we've determined that a retry should occur. The
-// arguments to the strategy are the retry number and the clock.
-func WithSleepStrategy(strategy func(retryNum int, clock Clock)) Arg {
-	return func(r *Retrier) *Retrier {
-		r.sleepStrategy = strategy
+// WithSleepStrategy sets a custom backoff strategy. This function
+// runs after we've determined that a retry should occur, and its
+// return value is the duration the Retrier sleeps for (interruptibly,
+// via the Clock) before the next attempt.
+func WithSleepStrategy(strategy func(retryNum int) time.Duration) Arg {
+	return func(cfg *retrierConfig) *retrierConfig {
+		cfg.backoff = strategy
+
+		return cfg
+	}
+}
+
+// WithAttemptTimeout bounds each individual attempt to d by wrapping
+// it in its own context.WithTimeout. This only has an effect on
+// retriers built with NewCtx/NewFullCtx (or their *WithData
+// counterparts), since only those pass the attempt's context through
+// to the retried function.
+func WithAttemptTimeout(d time.Duration) Arg {
+	return func(cfg *retrierConfig) *retrierConfig {
+		cfg.attemptTimeout = d
+
+		return cfg
+	}
+}
 
-		return r
+// WithOnRetry registers a hook that's called whenever an attempt
+// fails and a retry is about to happen, after retryCheck passes and
+// before the backoff sleep runs. retryNum is the attempt that just
+// failed, err is the error it returned, and nextSleep is the duration
+// that's about to be slept. Use this to log, emit metrics, or count
+// retries without having to write a custom backoff strategy just to
+// observe it.
+func WithOnRetry(onRetry func(retryNum int, err error, nextSleep time.Duration)) Arg {
+	return func(cfg *retrierConfig) *retrierConfig {
+		cfg.onRetry = onRetry
+
+		return cfg
 	}
 }
@@ -1,9 +1,12 @@
 package retries
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
+	"math"
+	"math/rand"
 	"reflect"
 	"testing"
 	"time"
@@ -25,6 +28,16 @@ func (c *clockMock) Now() time.Time {
 	return c.time
 }
 
+func (c *clockMock) SleepCtx(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.Sleep(d)
+
+	return nil
+}
+
 func TestDefault(t *testing.T) {
 	t.Run("retry-func", func(t *testing.T) {
 		check := false
@@ -100,6 +113,191 @@ func TestDefault(t *testing.T) {
 	})
 }
 
+func TestTryContext(t *testing.T) {
+	t.Run("func-ctx", func(t *testing.T) {
+		calls := 0
+		r := NewCtx(func(ctx context.Context) error {
+			calls++
+			return ctx.Err()
+		}, WithClock(&clockMock{}))
+
+		if err := r.TryContext(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if calls != 1 {
+			t.Fatal("invalid number of calls")
+		}
+	})
+
+	t.Run("cancelled-before-sleep", func(t *testing.T) {
+		c := &clockMock{}
+
+		e := errors.New("my error")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		calls := 0
+		r := New(func() error {
+			calls++
+			return e
+		}, WithClock(c), WithRetries(5))
+
+		err := r.TryContext(ctx)
+
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("invalid error returned: %s", err)
+		}
+		if calls != 1 {
+			t.Fatal("invalid number of calls")
+		}
+		if c.numSleeps != 0 {
+			t.Fatal("invalid number of sleeps")
+		}
+	})
+}
+
+func TestJitteredBackoff(t *testing.T) {
+	t.Run("full-jitter-caps-and-randomizes", func(t *testing.T) {
+		c := &clockMock{}
+
+		e := errors.New("my error")
+
+		calls := 0
+		r := New(func() error {
+			calls++
+			return e
+		}, WithClock(c), WithRetries(5), WithRand(rand.New(rand.NewSource(1))),
+			WithExpBackoffJitter(2, 5*time.Second))
+
+		if err := r.Try(); !errors.Is(err, e) {
+			t.Fatalf("invalid error returned: %s", err)
+		}
+		if c.numSleeps != 4 {
+			t.Fatal("invalid number of sleeps")
+		}
+		for i, d := range c.durs {
+			if d < 0 || d > 5*time.Second {
+				t.Fatalf("sleep %d out of bounds: %s", i, d)
+			}
+		}
+	})
+
+	t.Run("equal-jitter-keeps-a-floor", func(t *testing.T) {
+		c := &clockMock{}
+
+		e := errors.New("my error")
+
+		r := New(func() error {
+			return e
+		}, WithClock(c), WithRetries(5), WithRand(rand.New(rand.NewSource(1))),
+			WithExpBackoffEqualJitter(2, 5*time.Second))
+
+		if err := r.Try(); !errors.Is(err, e) {
+			t.Fatalf("invalid error returned: %s", err)
+		}
+		for i, d := range c.durs {
+			capped := time.Second * time.Duration(math.Pow(2, float64(i)))
+			if capped > 5*time.Second {
+				capped = 5 * time.Second
+			}
+			if d < capped/2 || d > capped {
+				t.Fatalf("sleep %d out of bounds: %s", i, d)
+			}
+		}
+	})
+}
+
+func TestOnRetry(t *testing.T) {
+	t.Run("fires-before-each-sleep", func(t *testing.T) {
+		c := &clockMock{}
+
+		e := errors.New("my error")
+
+		type call struct {
+			retryNum  int
+			err       error
+			nextSleep time.Duration
+		}
+		var calls []call
+
+		r := New(func() error {
+			return e
+		}, WithClock(c), WithRetries(3), WithOnRetry(func(retryNum int, err error, nextSleep time.Duration) {
+			calls = append(calls, call{retryNum, err, nextSleep})
+		}))
+
+		if err := r.Try(); !errors.Is(err, e) {
+			t.Fatalf("invalid error returned: %s", err)
+		}
+		if len(calls) != 2 {
+			t.Fatalf("invalid number of onRetry calls: %d", len(calls))
+		}
+		for i, c := range calls {
+			if c.retryNum != i {
+				t.Fatalf("invalid retry number: %d", c.retryNum)
+			}
+			if !errors.Is(c.err, e) {
+				t.Fatalf("invalid error passed to onRetry: %s", c.err)
+			}
+			if c.nextSleep != time.Second*time.Duration(1<<uint(i)) {
+				t.Fatalf("invalid nextSleep passed to onRetry: %s", c.nextSleep)
+			}
+		}
+	})
+
+	t.Run("does-not-fire-on-success", func(t *testing.T) {
+		calls := 0
+		r := New(func() error {
+			return nil
+		}, WithClock(&clockMock{}), WithOnRetry(func(int, error, time.Duration) {
+			calls++
+		}))
+
+		if err := r.Try(); err != nil {
+			t.Fatal(err)
+		}
+		if calls != 0 {
+			t.Fatal("onRetry should not have been called")
+		}
+	})
+}
+
+func TestAttemptTimeout(t *testing.T) {
+	t.Run("attempt-is-cancelled-on-timeout", func(t *testing.T) {
+		calls := 0
+		r := NewCtx(func(ctx context.Context) error {
+			calls++
+			<-ctx.Done()
+			return ctx.Err()
+		}, WithClock(&clockMock{}), WithRetries(1), WithAttemptTimeout(time.Millisecond))
+
+		err := r.Try()
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("invalid error returned: %s", err)
+		}
+		if calls != 1 {
+			t.Fatal("invalid number of calls")
+		}
+	})
+
+	t.Run("outer-ctx-not-cancelled-by-attempt-timeout", func(t *testing.T) {
+		r := NewCtx(func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, WithClock(&clockMock{}), WithRetries(1), WithAttemptTimeout(time.Millisecond))
+
+		ctx := context.Background()
+		if err := r.TryContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("invalid error returned: %s", err)
+		}
+		if ctx.Err() != nil {
+			t.Fatal("outer context should not have been cancelled")
+		}
+	})
+}
+
 func TestWhitelist(t *testing.T) {
 	t.Run("simple-whitelist", func(t *testing.T) {
 		c := &clockMock{}
@@ -132,6 +330,9 @@ func TestWhitelist(t *testing.T) {
 		c := &clockMock{}
 		calls := 0
 
+		// A distinct error instance with the same message no longer
+		// matches: errors.Is compares identity (or Is methods), not
+		// error strings.
 		r := New(func() error {
 			calls++
 			return errors.New("my error")
@@ -142,15 +343,12 @@ func TestWhitelist(t *testing.T) {
 		if err.Error() != "my error" {
 			t.Fatal("invalid error returned")
 		}
-		if calls != 5 {
+		if calls != 1 {
 			t.Fatalf("invalid number of calls: %d", calls)
 		}
-		if c.numSleeps != 4 {
+		if c.numSleeps != 0 {
 			t.Fatal("invalid number of sleeps")
 		}
-		if !reflect.DeepEqual(c.durs, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second}) {
-			t.Fatalf("invalid sleep durations: %#v", c.durs)
-		}
 	})
 
 	t.Run("no-match", func(t *testing.T) {
@@ -204,7 +402,7 @@ func TestWhitelist(t *testing.T) {
 		}
 	})
 
-	t.Run("substring error", func(t *testing.T) {
+	t.Run("substring error no longer matches", func(t *testing.T) {
 		c := &clockMock{}
 
 		e := errors.New("really long error")
@@ -220,15 +418,12 @@ func TestWhitelist(t *testing.T) {
 		if err.Error() != "my really long error" {
 			t.Fatalf("invalid error returned: %s", err)
 		}
-		if calls != 5 {
+		if calls != 1 {
 			t.Fatal("invalid number of calls")
 		}
-		if c.numSleeps != 4 {
+		if c.numSleeps != 0 {
 			t.Fatal("invalid number of sleeps")
 		}
-		if !reflect.DeepEqual(c.durs, []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second}) {
-			t.Fatalf("invalid sleep durations: %#v", c.durs)
-		}
 	})
 }
 
@@ -0,0 +1,82 @@
+package retries
+
+import (
+	"context"
+	"reflect"
+)
+
+// watch is a single channel registered with WithWatch.
+type watch struct {
+	name  string
+	value reflect.Value
+	fn    func(context.Context) error
+}
+
+// WithWatch registers a channel with a Retrier, turning it into a
+// long-lived supervisor when run with Run: whenever a value arrives
+// on ch, fn is invoked (under the Retrier's configured retries and
+// backoff) to react to it. The value received from ch is only used to
+// wake up the select loop; it isn't passed to fn. name identifies the
+// watch for callers building multiple watches on the same Retrier.
+func WithWatch[T any](name string, ch <-chan T, fn func(context.Context) error) Arg {
+	return func(cfg *retrierConfig) *retrierConfig {
+		cfg.watches = append(cfg.watches, watch{
+			name:  name,
+			value: reflect.ValueOf(ch),
+			fn:    fn,
+		})
+
+		return cfg
+	}
+}
+
+// Run turns the Retrier into a supervisor: it invokes the primary
+// function with the usual retries and backoff, then, once that
+// succeeds, blocks reacting to any channels registered with
+// WithWatch, running their functions (also under retry) as values
+// arrive. Each invocation gets a fresh backoff sequence, so a failing
+// watched function backs off independently of the others and of the
+// startup call. Run returns when the primary function exhausts its
+// retries, or when ctx is done.
+func (r *Retrier) Run(ctx context.Context) error {
+	if err := r.TryContext(ctx); err != nil {
+		return err
+	}
+
+	watches := r.inner.cfg.watches
+	if len(watches) == 0 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	cases := make([]reflect.SelectCase, len(watches)+1)
+	cases[0] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())}
+	for i, w := range watches {
+		cases[i+1] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: w.value}
+	}
+
+	for {
+		chosen, _, ok := reflect.Select(cases)
+		if chosen == 0 {
+			return ctx.Err()
+		}
+
+		if !ok {
+			// The channel was closed; stop selecting on it.
+			cases[chosen].Chan = reflect.Value{}
+			continue
+		}
+
+		_ = r.withFunc(watches[chosen-1].fn).TryContext(ctx)
+	}
+}
+
+// withFunc returns a Retrier that shares this Retrier's
+// configuration, but retries f instead of the original function.
+func (r *Retrier) withFunc(f func(context.Context) error) *Retrier {
+	wrapped := DataFuncCtx[struct{}](func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, f(ctx)
+	})
+
+	return &Retrier{inner: &DataRetrier[struct{}]{f: wrapped, cfg: r.inner.cfg}}
+}
@@ -0,0 +1,90 @@
+package retries
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	t.Run("runs-startup-and-watch", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		events := make(chan int)
+
+		startupCalls := 0
+		watchCalls := 0
+		done := make(chan struct{})
+
+		r := New(func() error {
+			startupCalls++
+			return nil
+		}, WithClock(&clockMock{}), WithWatch("events", events, func(context.Context) error {
+			watchCalls++
+			close(done)
+			return nil
+		}))
+
+		go func() {
+			_ = r.Run(ctx)
+		}()
+
+		events <- 1
+
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("watch function was never called")
+		}
+
+		if startupCalls != 1 {
+			t.Fatalf("invalid number of startup calls: %d", startupCalls)
+		}
+		if watchCalls != 1 {
+			t.Fatalf("invalid number of watch calls: %d", watchCalls)
+		}
+	})
+
+	t.Run("startup-failure-aborts-run", func(t *testing.T) {
+		e := errString("startup error")
+
+		r := New(func() error {
+			return e
+		}, WithClock(&clockMock{}), WithRetries(1))
+
+		if err := r.Run(context.Background()); err != e {
+			t.Fatalf("invalid error returned: %v", err)
+		}
+	})
+
+	t.Run("ctx-done-stops-run", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		r := New(func() error {
+			return nil
+		}, WithClock(&clockMock{}))
+
+		runErr := make(chan error, 1)
+		go func() {
+			runErr <- r.Run(ctx)
+		}()
+
+		cancel()
+
+		select {
+		case err := <-runErr:
+			if err != context.Canceled {
+				t.Fatalf("invalid error returned: %v", err)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Run never returned after ctx was cancelled")
+		}
+	})
+}
+
+type errString string
+
+func (e errString) Error() string {
+	return string(e)
+}